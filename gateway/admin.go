@@ -0,0 +1,87 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+// readinessChecker is the subset of Gateway that the admin server's /readyz handler depends on, split out
+// so the mux can be built and tested without a fully running Gateway.
+type readinessChecker interface {
+	Ready() bool
+}
+
+// newAdminMux builds the admin server's handler tree: /debug/pprof/* (only when -PProf is set), /debug/vars
+// (the internal/metrics expvar registry), /healthz (process liveness), and /readyz (backed by readiness).
+func newAdminMux(readiness readinessChecker) (*http.ServeMux, error) {
+	mux := http.NewServeMux()
+
+	if PProf {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if readiness.Ready() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	return mux, nil
+}
+
+// StartAdminServer starts the always-on admin HTTP server on config.AdminListenAddress (default
+// 127.0.0.1:6161). The returned func shuts the server down and should be appended to Main's deferred
+// cleanups.
+func StartAdminServer(config *configuration.GatewayConfig, gateway *Gateway) (func(), error) {
+	mux, err := newAdminMux(gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	address := config.AdminListenAddress
+	if address == "" {
+		address = "127.0.0.1:6161"
+	}
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			config.Log.Error().Err(err).Msgf("Admin server on %s exited with an error.", address)
+		}
+	}()
+	config.Log.Info().Msgf("Launched admin server on %s", address)
+
+	return func() {
+		config.Log.Info().Msg("Shutting down admin server.")
+		_ = server.Close()
+	}, nil
+}
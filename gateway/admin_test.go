@@ -0,0 +1,68 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeReadiness struct {
+	ready bool
+}
+
+func (f *fakeReadiness) Ready() bool { return f.ready }
+
+func TestAdminHealthzAlwaysOK(t *testing.T) {
+	mux, _ := newAdminMux(&fakeReadiness{ready: false})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAdminReadyzReflectsGatewayReadiness(t *testing.T) {
+	readiness := &fakeReadiness{ready: false}
+	mux, _ := newAdminMux(readiness)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz status = %d, want %d when not ready", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	readiness.ready = true
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/readyz status = %d, want %d when ready", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAdminDebugVarsIsMounted(t *testing.T) {
+	mux, _ := newAdminMux(&fakeReadiness{ready: true})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("/debug/vars status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
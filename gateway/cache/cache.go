@@ -0,0 +1,69 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache holds the most recent Notification received for each (target, prefix) pair so that a new gNMI client can
+// be synced immediately instead of waiting for every leaf to update again.
+package cache
+
+import (
+	"context"
+	"sync"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmi-gateway/gateway/internal/metrics"
+	"github.com/openconfig/gnmi-gateway/gateway/tracing"
+)
+
+// Cache stores the latest Notification received for each (target, prefix) pair.
+type Cache struct {
+	mu     sync.Mutex
+	leaves map[string]*gnmipb.Notification
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{leaves: make(map[string]*gnmipb.Notification)}
+}
+
+// Update records the Notification carried by response (if any) as the latest state for target. It is a
+// no-op for SubscribeResponse messages that don't carry an update (e.g. sync_response).
+func (c *Cache) Update(ctx context.Context, target string, response *gnmipb.SubscribeResponse) {
+	_, span := tracing.StartSpan(ctx, "cache.Update", map[string]string{"target": target})
+	defer span.End()
+
+	notification := response.GetUpdate()
+	if notification == nil {
+		return
+	}
+
+	key := target + "|" + notification.GetPrefix().String()
+	c.mu.Lock()
+	_, existed := c.leaves[key]
+	c.leaves[key] = notification
+	size := len(c.leaves)
+	c.mu.Unlock()
+
+	if !existed {
+		metrics.CacheSize.Set(int64(size))
+	}
+}
+
+// Size returns the number of distinct (target, prefix) entries currently cached.
+func (c *Cache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.leaves)
+}
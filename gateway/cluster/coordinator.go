@@ -0,0 +1,95 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cluster provides the coordination primitives (leader election, target sharding locks, and
+// membership) that allow multiple gnmi-gateway instances to operate as a single logical cluster. The
+// Coordinator interface abstracts the backend (Zookeeper, etcd, or none) so the rest of the gateway never
+// needs to know which one is in use.
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+// Coordinator is implemented by every cluster coordination backend. A single Coordinator instance is owned
+// by the Gateway for the lifetime of the process.
+type Coordinator interface {
+	// Start opens the connection to the coordination backend. It must be called before any other method.
+	Start() error
+	// IsLeader returns true if this instance currently holds cluster leadership.
+	IsLeader() bool
+	// LeaderElection blocks until this instance becomes the cluster leader or ctx is cancelled, in which
+	// case ctx.Err() is returned.
+	LeaderElection(ctx context.Context) error
+	// AcquireTargetLock attempts to acquire an exclusive lock on targetName so that this instance is the
+	// only one connecting to that target. ok is false if the lock is already held by another member.
+	AcquireTargetLock(targetName string) (ok bool, err error)
+	// ReleaseTargetLock releases a lock on targetName previously acquired with AcquireTargetLock.
+	ReleaseTargetLock(targetName string) error
+	// RegisterMember announces this instance's presence to the rest of the cluster under memberID.
+	RegisterMember(memberID string) error
+	// DeregisterMember removes this instance from the cluster membership list. It is safe to call during
+	// shutdown even if RegisterMember was never called.
+	DeregisterMember(memberID string) error
+	// WatchMembers returns a channel that receives the current member list every time cluster membership
+	// changes. The channel is closed when ctx is cancelled.
+	WatchMembers(ctx context.Context) (<-chan []string, error)
+	// Close releases the leader lock (if held), all target locks held by this instance, and closes the
+	// connection to the coordination backend. Close is safe to call multiple times.
+	Close() error
+}
+
+// NewCoordinator builds the Coordinator configured by config.ClusterBackend. An error is returned if the
+// selected backend is misconfigured (e.g. no hosts/endpoints provided).
+func NewCoordinator(config *configuration.GatewayConfig) (Coordinator, error) {
+	switch config.ClusterBackend {
+	case "", "zookeeper":
+		return NewZookeeperCoordinator(config)
+	case "etcd":
+		return NewEtcdCoordinator(config)
+	case "none":
+		return NewNoopCoordinator(), nil
+	default:
+		return nil, fmt.Errorf("unknown -ClusterBackend %q: must be one of zookeeper, etcd, none", config.ClusterBackend)
+	}
+}
+
+// NewNoopCoordinator returns a Coordinator for standalone (single-instance, non-clustered) deployments.
+// This instance is always the leader and every lock acquisition succeeds immediately.
+func NewNoopCoordinator() Coordinator {
+	return &noopCoordinator{}
+}
+
+type noopCoordinator struct{}
+
+func (*noopCoordinator) Start() error                           { return nil }
+func (*noopCoordinator) IsLeader() bool                         { return true }
+func (*noopCoordinator) LeaderElection(context.Context) error   { return nil }
+func (*noopCoordinator) AcquireTargetLock(string) (bool, error) { return true, nil }
+func (*noopCoordinator) ReleaseTargetLock(string) error         { return nil }
+func (*noopCoordinator) RegisterMember(string) error            { return nil }
+func (*noopCoordinator) DeregisterMember(string) error          { return nil }
+func (*noopCoordinator) WatchMembers(ctx context.Context) (<-chan []string, error) {
+	ch := make(chan []string)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+func (*noopCoordinator) Close() error { return nil }
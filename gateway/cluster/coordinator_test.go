@@ -0,0 +1,114 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+func TestNewCoordinatorNone(t *testing.T) {
+	config := configuration.NewDefaultGatewayConfig()
+	config.ClusterBackend = "none"
+
+	coordinator, err := NewCoordinator(config)
+	if err != nil {
+		t.Fatalf("NewCoordinator returned an error: %v", err)
+	}
+	if _, ok := coordinator.(*noopCoordinator); !ok {
+		t.Fatalf("NewCoordinator returned %T, want *noopCoordinator", coordinator)
+	}
+}
+
+func TestNewCoordinatorUnknownBackend(t *testing.T) {
+	config := configuration.NewDefaultGatewayConfig()
+	config.ClusterBackend = "bogus"
+
+	if _, err := NewCoordinator(config); err == nil {
+		t.Fatal("NewCoordinator with an unknown backend returned a nil error")
+	}
+}
+
+func TestNewCoordinatorZookeeperRequiresHosts(t *testing.T) {
+	config := configuration.NewDefaultGatewayConfig()
+	config.ClusterBackend = "zookeeper"
+
+	if _, err := NewCoordinator(config); err == nil {
+		t.Fatal("NewCoordinator with no -ZookeeperHosts returned a nil error")
+	}
+}
+
+func TestNewCoordinatorEtcdRequiresEndpoints(t *testing.T) {
+	config := configuration.NewDefaultGatewayConfig()
+	config.ClusterBackend = "etcd"
+
+	if _, err := NewCoordinator(config); err == nil {
+		t.Fatal("NewCoordinator with no -EtcdEndpoints returned a nil error")
+	}
+}
+
+func TestNoopCoordinator(t *testing.T) {
+	coordinator := NewNoopCoordinator()
+
+	if err := coordinator.Start(); err != nil {
+		t.Fatalf("Start returned an error: %v", err)
+	}
+	if !coordinator.IsLeader() {
+		t.Fatal("IsLeader() = false, want true")
+	}
+	if err := coordinator.LeaderElection(context.Background()); err != nil {
+		t.Fatalf("LeaderElection returned an error: %v", err)
+	}
+	ok, err := coordinator.AcquireTargetLock("target-1")
+	if err != nil || !ok {
+		t.Fatalf("AcquireTargetLock = (%v, %v), want (true, nil)", ok, err)
+	}
+	if err := coordinator.ReleaseTargetLock("target-1"); err != nil {
+		t.Fatalf("ReleaseTargetLock returned an error: %v", err)
+	}
+	if err := coordinator.RegisterMember("member-1"); err != nil {
+		t.Fatalf("RegisterMember returned an error: %v", err)
+	}
+	if err := coordinator.DeregisterMember("member-1"); err != nil {
+		t.Fatalf("DeregisterMember returned an error: %v", err)
+	}
+	if err := coordinator.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+}
+
+func TestNoopCoordinatorWatchMembersClosesOnCancel(t *testing.T) {
+	coordinator := NewNoopCoordinator()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := coordinator.WatchMembers(ctx)
+	if err != nil {
+		t.Fatalf("WatchMembers returned an error: %v", err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("WatchMembers channel delivered a value instead of closing")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WatchMembers channel was never closed")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/tracing"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// etcdCoordinator is a Coordinator backed by etcd. Leader election and target locks are both built on
+// concurrency.Session (a lease that is kept alive for the life of the process) so that a crashed instance's
+// locks are released automatically once its lease expires.
+type etcdCoordinator struct {
+	endpoints   []string
+	dialTimeout time.Duration
+	prefix      string
+
+	client  *clientv3.Client
+	session *concurrency.Session
+
+	mu        sync.Mutex
+	leader    bool
+	targetMus map[string]*concurrency.Mutex
+}
+
+// NewEtcdCoordinator returns a Coordinator backed by etcd using -EtcdEndpoints, -EtcdPrefix, and
+// -EtcdDialTimeout.
+func NewEtcdCoordinator(config *configuration.GatewayConfig) (Coordinator, error) {
+	if len(config.EtcdEndpoints) == 0 {
+		return nil, fmt.Errorf("-EtcdEndpoints must be set to use the etcd cluster backend")
+	}
+	prefix := config.EtcdPrefix
+	if prefix == "" {
+		prefix = "/gnmi/gateway/"
+	}
+	return &etcdCoordinator{
+		endpoints:   config.EtcdEndpoints,
+		dialTimeout: config.EtcdDialTimeout,
+		prefix:      prefix,
+		targetMus:   make(map[string]*concurrency.Mutex),
+	}, nil
+}
+
+func (e *etcdCoordinator) Start() error {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   e.endpoints,
+		DialTimeout: e.dialTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to connect to etcd: %v", err)
+	}
+	session, err := concurrency.NewSession(client)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("unable to create etcd session: %v", err)
+	}
+	e.client = client
+	e.session = session
+	return nil
+}
+
+func (e *etcdCoordinator) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+func (e *etcdCoordinator) LeaderElection(ctx context.Context) error {
+	election := concurrency.NewElection(e.session, path.Join(e.prefix, "leader"))
+	if err := election.Campaign(ctx, "member"); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.leader = true
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *etcdCoordinator) AcquireTargetLock(targetName string) (bool, error) {
+	_, span := tracing.StartSpan(context.Background(), "cluster.AcquireTargetLock", map[string]string{"target": targetName, "backend": "etcd"})
+	defer span.End()
+
+	mu := concurrency.NewMutex(e.session, path.Join(e.prefix, "locks", targetName))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := mu.TryLock(ctx); err != nil {
+		if err == concurrency.ErrLocked {
+			return false, nil
+		}
+		return false, err
+	}
+	e.mu.Lock()
+	e.targetMus[targetName] = mu
+	e.mu.Unlock()
+	return true, nil
+}
+
+func (e *etcdCoordinator) ReleaseTargetLock(targetName string) error {
+	e.mu.Lock()
+	mu, ok := e.targetMus[targetName]
+	delete(e.targetMus, targetName)
+	e.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return mu.Unlock(context.Background())
+}
+
+func (e *etcdCoordinator) RegisterMember(memberID string) error {
+	_, err := e.client.Put(context.Background(), path.Join(e.prefix, "members", memberID), memberID, clientv3.WithLease(e.session.Lease()))
+	return err
+}
+
+func (e *etcdCoordinator) DeregisterMember(memberID string) error {
+	_, err := e.client.Delete(context.Background(), path.Join(e.prefix, "members", memberID))
+	return err
+}
+
+func (e *etcdCoordinator) WatchMembers(ctx context.Context) (<-chan []string, error) {
+	membersPrefix := path.Join(e.prefix, "members")
+	// Buffered so the initial member list below can be sent before the channel is returned to the caller.
+	out := make(chan []string, 1)
+
+	emit := func() error {
+		resp, err := e.client.Get(ctx, membersPrefix, clientv3.WithPrefix())
+		if err != nil {
+			return err
+		}
+		members := make([]string, 0, len(resp.Kvs))
+		for _, kv := range resp.Kvs {
+			members = append(members, string(kv.Value))
+		}
+		select {
+		case out <- members:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+
+	if err := emit(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(out)
+		watch := e.client.Watch(ctx, membersPrefix, clientv3.WithPrefix())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watch:
+				if !ok {
+					return
+				}
+				if err := emit(); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (e *etcdCoordinator) Close() error {
+	e.mu.Lock()
+	targets := make([]string, 0, len(e.targetMus))
+	for target := range e.targetMus {
+		targets = append(targets, target)
+	}
+	e.leader = false
+	e.mu.Unlock()
+	for _, target := range targets {
+		_ = e.ReleaseTargetLock(target)
+	}
+	if e.session != nil {
+		_ = e.session.Close()
+		e.session = nil
+	}
+	if e.client != nil {
+		client := e.client
+		e.client = nil
+		return client.Close()
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/tracing"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// zookeeperCoordinator is the original cluster coordination backend. It uses a single Zookeeper ensemble
+// for both leader election (an ephemeral sequential node under <prefix>/leader) and per-target locks (an
+// ephemeral node under <prefix>/locks/<target>).
+type zookeeperCoordinator struct {
+	hosts   []string
+	prefix  string
+	timeout time.Duration
+	conn    *zk.Conn
+	leader  bool
+}
+
+// NewZookeeperCoordinator returns a Coordinator backed by Zookeeper using -ZookeeperHosts, -ZookeeperPrefix,
+// and -ZookeeperTimeout.
+func NewZookeeperCoordinator(config *configuration.GatewayConfig) (Coordinator, error) {
+	if len(config.ZookeeperHosts) == 0 {
+		return nil, fmt.Errorf("-ZookeeperHosts must be set to use the zookeeper cluster backend")
+	}
+	return &zookeeperCoordinator{
+		hosts:   config.ZookeeperHosts,
+		prefix:  config.ZookeeperPrefix,
+		timeout: config.ZookeeperTimeout,
+	}, nil
+}
+
+func (z *zookeeperCoordinator) Start() error {
+	conn, _, err := zk.Connect(z.hosts, z.timeout)
+	if err != nil {
+		return fmt.Errorf("unable to connect to Zookeeper: %v", err)
+	}
+	z.conn = conn
+	return z.ensurePath(z.prefix)
+}
+
+func (z *zookeeperCoordinator) ensurePath(p string) error {
+	exists, _, err := z.conn.Exists(p)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = z.conn.Create(p, nil, 0, zk.WorldACL(zk.PermAll))
+		if err != nil && err != zk.ErrNodeExists {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *zookeeperCoordinator) IsLeader() bool {
+	return z.leader
+}
+
+func (z *zookeeperCoordinator) LeaderElection(ctx context.Context) error {
+	electionPath := path.Join(z.prefix, "leader")
+	if err := z.ensurePath(electionPath); err != nil {
+		return err
+	}
+	myNode, err := z.conn.CreateProtectedEphemeralSequential(electionPath+"/n_", nil, zk.WorldACL(zk.PermAll))
+	if err != nil {
+		return fmt.Errorf("unable to create election node: %v", err)
+	}
+	myName := path.Base(myNode)
+
+	for {
+		children, _, err := z.conn.Children(electionPath)
+		if err != nil {
+			return err
+		}
+		sort.Strings(children)
+		if len(children) > 0 && children[0] == myName {
+			z.leader = true
+			return nil
+		}
+
+		// Watch the node immediately before ours so we don't thundering-herd on every change.
+		predecessor := ""
+		for _, child := range children {
+			if child < myName && child > predecessor {
+				predecessor = child
+			}
+		}
+		if predecessor == "" {
+			// Lost track of ordering, retry the listing.
+			continue
+		}
+		_, _, events, err := z.conn.ExistsW(path.Join(electionPath, predecessor))
+		if err != nil {
+			continue
+		}
+		select {
+		case <-events:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (z *zookeeperCoordinator) AcquireTargetLock(targetName string) (bool, error) {
+	_, span := tracing.StartSpan(context.Background(), "cluster.AcquireTargetLock", map[string]string{"target": targetName, "backend": "zookeeper"})
+	defer span.End()
+
+	lockPath := path.Join(z.prefix, "locks")
+	if err := z.ensurePath(lockPath); err != nil {
+		return false, err
+	}
+	_, err := z.conn.Create(path.Join(lockPath, sanitize(targetName)), nil, zk.FlagEphemeral, zk.WorldACL(zk.PermAll))
+	if err == zk.ErrNodeExists {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (z *zookeeperCoordinator) ReleaseTargetLock(targetName string) error {
+	err := z.conn.Delete(path.Join(z.prefix, "locks", sanitize(targetName)), -1)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+func (z *zookeeperCoordinator) RegisterMember(memberID string) error {
+	membersPath := path.Join(z.prefix, "members")
+	if err := z.ensurePath(membersPath); err != nil {
+		return err
+	}
+	_, err := z.conn.Create(path.Join(membersPath, sanitize(memberID)), nil, zk.FlagEphemeral, zk.WorldACL(zk.PermAll))
+	if err == zk.ErrNodeExists {
+		return nil
+	}
+	return err
+}
+
+func (z *zookeeperCoordinator) DeregisterMember(memberID string) error {
+	err := z.conn.Delete(path.Join(z.prefix, "members", sanitize(memberID)), -1)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+func (z *zookeeperCoordinator) WatchMembers(ctx context.Context) (<-chan []string, error) {
+	membersPath := path.Join(z.prefix, "members")
+	if err := z.ensurePath(membersPath); err != nil {
+		return nil, err
+	}
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+		for {
+			children, _, events, err := z.conn.ChildrenW(membersPath)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- children:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-events:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (z *zookeeperCoordinator) Close() error {
+	if z.conn != nil {
+		z.conn.Close()
+		z.conn = nil
+	}
+	z.leader = false
+	return nil
+}
+
+func sanitize(name string) string {
+	return strings.ReplaceAll(name, "/", "_")
+}
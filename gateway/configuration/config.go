@@ -0,0 +1,113 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configuration holds the GatewayConfig struct that every other gateway package is configured
+// through, along with the helpers used to populate it from a JSON file.
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// GatewayConfig holds every setting the gateway and its subsystems are configured with. ParseArgs populates
+// it from command-line flags, PopulateGatewayConfigFromFile overlays a JSON file on top, and envconfig.Process
+// overlays the environment on top of that.
+type GatewayConfig struct {
+	// Log is not populated from the config file or the environment; it is set up by NewDefaultGatewayConfig
+	// and mutated in place by -LogCaller.
+	Log zerolog.Logger `json:"-"`
+
+	// AdminListenAddress is the address the admin HTTP server (pprof, expvar, health/readiness) listens on.
+	AdminListenAddress string
+	// ClusterMemberID identifies this instance to the rest of the cluster (membership, and as a trace
+	// resource attribute so multi-instance traces stitch together). Defaults to the hostname.
+	ClusterMemberID string
+	// ClusterBackend selects the cluster.Coordinator implementation: "zookeeper", "etcd", or "none".
+	ClusterBackend string
+
+	EnableGNMIServer bool
+
+	Exporters struct {
+		Enabled []string
+	}
+
+	EtcdEndpoints   []string
+	EtcdPrefix      string
+	EtcdDialTimeout time.Duration
+
+	GatewayTransitionBufferSize uint64
+	LogCaller                   bool
+	OpenConfigDirectory         string
+
+	ServerAddress       string
+	ServerPort          int
+	ServerListenAddress string
+	ServerListenPort    int
+	ServerTLSCert       string
+	ServerTLSKey        string
+
+	ShutdownTimeout time.Duration
+
+	StatsSpectatorURI string
+
+	TargetLoaders struct {
+		Enabled                  []string
+		SimpleFile               string
+		SimpleFileReloadInterval time.Duration
+		JSONFile                 string
+		JSONFileReloadInterval   time.Duration
+	}
+	TargetDialTimeout time.Duration
+	TargetLimit       int
+
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingSampleRatio float64
+
+	ZookeeperHosts   []string
+	ZookeeperPrefix  string
+	ZookeeperTimeout time.Duration
+}
+
+// NewDefaultGatewayConfig returns a GatewayConfig with a configured Log and a ClusterMemberID derived from
+// the hostname. Every other field is left at its zero value; ParseArgs is responsible for flag defaults.
+func NewDefaultGatewayConfig() *GatewayConfig {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &GatewayConfig{
+		Log:             zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger(),
+		ClusterMemberID: hostname,
+	}
+}
+
+// PopulateGatewayConfigFromFile unmarshals the JSON file at path on top of the fields already set on
+// config. Log is excluded (tagged json:"-") so the logger already configured on config is preserved.
+func PopulateGatewayConfigFromFile(config *GatewayConfig, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config file %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("unable to parse config file %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connections owns every outbound gNMI Subscribe stream the gateway holds open to a target. Manager
+// loads the target list from the configured TargetLoaders, dials each target (taking its cluster lock
+// first, if a cluster.Coordinator is in use), and fans every SubscribeResponse it receives out to the cache
+// and every configured Exporter.
+package connections
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/openconfig/gnmi-gateway/gateway/cache"
+	"github.com/openconfig/gnmi-gateway/gateway/cluster"
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/exporters"
+	"github.com/openconfig/gnmi-gateway/gateway/internal/metrics"
+	"github.com/openconfig/gnmi-gateway/gateway/loaders"
+	"github.com/openconfig/gnmi-gateway/gateway/tracing"
+)
+
+// Manager dials every target returned by the configured TargetLoaders and streams their updates into the
+// cache and exporters.
+type Manager struct {
+	mu          sync.Mutex
+	config      *configuration.GatewayConfig
+	coordinator cluster.Coordinator
+	cache       *cache.Cache
+	exporters   []exporters.Exporter
+	broadcast   func(*gnmipb.SubscribeResponse)
+
+	accepting  bool
+	loadedOnce bool
+	targets    map[string]context.CancelFunc
+
+	exporterQueueDepth sync.Map // exporter name -> *int64, the exporter's current in-flight Export call count
+}
+
+// NewManager returns a Manager that will dial targets using config, guarded by coordinator's per-target
+// locks, writing updates into c and fanning them out to exps.
+func NewManager(config *configuration.GatewayConfig, coordinator cluster.Coordinator, c *cache.Cache, exps []exporters.Exporter) *Manager {
+	return &Manager{
+		config:      config,
+		coordinator: coordinator,
+		cache:       c,
+		exporters:   exps,
+		targets:     make(map[string]context.CancelFunc),
+	}
+}
+
+// SetBroadcast registers fn to be called with every SubscribeResponse received from any target, in addition
+// to the cache and exporter fan-out. The Gateway uses this to relay updates to its own SubscribeStream
+// clients.
+func (m *Manager) SetBroadcast(fn func(*gnmipb.SubscribeResponse)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.broadcast = fn
+}
+
+// Start begins accepting new target connections and loads the initial target list.
+func (m *Manager) Start() error {
+	m.mu.Lock()
+	m.accepting = true
+	m.mu.Unlock()
+	return m.loadTargets(m.config)
+}
+
+func (m *Manager) loadTargets(config *configuration.GatewayConfig) error {
+	var loadErr error
+	for _, name := range config.TargetLoaders.Enabled {
+		loader, err := loaders.New(name, config)
+		if err != nil {
+			config.Log.Error().Err(err).Msgf("Unable to create target loader %q.", name)
+			loadErr = err
+			continue
+		}
+		targets, err := loader.Load()
+		if err != nil {
+			config.Log.Error().Err(err).Msgf("Target loader %q failed to load targets.", name)
+			loadErr = err
+			continue
+		}
+		m.mu.Lock()
+		m.loadedOnce = true
+		m.mu.Unlock()
+		for _, target := range targets {
+			m.Connect(target)
+		}
+	}
+	return loadErr
+}
+
+// Connect dials target and streams its updates, unless the Manager has stopped accepting new connections,
+// is already connected to target, is at its TargetLimit, or another cluster member already holds target's
+// lock.
+func (m *Manager) Connect(target string) {
+	m.mu.Lock()
+	accepting := m.accepting
+	config := m.config
+	_, alreadyConnected := m.targets[target]
+	connected := len(m.targets)
+	m.mu.Unlock()
+
+	if !accepting || alreadyConnected {
+		return
+	}
+	if config.TargetLimit > 0 && connected >= config.TargetLimit {
+		config.Log.Warn().Msgf("Not connecting to %s: at the %d target limit.", target, config.TargetLimit)
+		return
+	}
+
+	if m.coordinator != nil {
+		ok, err := m.coordinator.AcquireTargetLock(target)
+		if err != nil {
+			config.Log.Error().Err(err).Msgf("Unable to acquire cluster lock for target %s.", target)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+
+	m.mu.Lock()
+	if _, exists := m.targets[target]; exists {
+		m.mu.Unlock()
+		if m.coordinator != nil {
+			_ = m.coordinator.ReleaseTargetLock(target)
+		}
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.targets[target] = cancel
+	m.mu.Unlock()
+
+	metrics.ConnectedTargets.Add(1)
+	go m.run(ctx, target)
+}
+
+// run dials target, streams its Subscribe responses until ctx is cancelled or the stream ends in an error,
+// and retries until ctx is cancelled. A failed dial is retried after waiting the target's dial timeout; a
+// stream that ends in an error is redialled immediately.
+func (m *Manager) run(ctx context.Context, target string) {
+	defer func() {
+		if m.coordinator != nil {
+			_ = m.coordinator.ReleaseTargetLock(target)
+		}
+		m.mu.Lock()
+		delete(m.targets, target)
+		m.mu.Unlock()
+		metrics.ConnectedTargets.Add(-1)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		dialCtx, span := tracing.StartSpan(ctx, "connections.Dial", map[string]string{"target": target})
+		conn, err := m.dial(dialCtx, target)
+		span.End()
+		if err != nil {
+			m.config.Log.Error().Err(err).Msgf("Unable to dial target %s, will retry.", target)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(m.config.TargetDialTimeout):
+				continue
+			}
+		}
+
+		err = m.streamSubscriptions(ctx, target, conn)
+		_ = conn.Close()
+		if err != nil {
+			m.config.Log.Warn().Err(err).Msgf("Subscription to %s ended, reconnecting.", target)
+			continue
+		}
+		return
+	}
+}
+
+func (m *Manager) dial(ctx context.Context, target string) (*grpc.ClientConn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, m.config.TargetDialTimeout)
+	defer cancel()
+	return grpc.DialContext(dialCtx, target, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+}
+
+func (m *Manager) streamSubscriptions(ctx context.Context, target string, conn *grpc.ClientConn) error {
+	client := gnmipb.NewGNMIClient(conn)
+	stream, err := client.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open Subscribe stream to %s: %v", target, err)
+	}
+	request := &gnmipb.SubscribeRequest{
+		Request: &gnmipb.SubscribeRequest_Subscribe{
+			Subscribe: &gnmipb.SubscriptionList{Mode: gnmipb.SubscriptionList_STREAM},
+		},
+	}
+	if err := stream.Send(request); err != nil {
+		return fmt.Errorf("unable to send subscribe request to %s: %v", target, err)
+	}
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		m.handleUpdate(ctx, target, response)
+	}
+}
+
+func (m *Manager) handleUpdate(ctx context.Context, target string, response *gnmipb.SubscribeResponse) {
+	updateCtx, span := tracing.StartSpan(ctx, "connections.HandleUpdate", map[string]string{"target": target})
+	defer span.End()
+
+	if m.cache != nil {
+		m.cache.Update(updateCtx, target, response)
+	}
+
+	m.mu.Lock()
+	exps := append([]exporters.Exporter(nil), m.exporters...)
+	broadcast := m.broadcast
+	m.mu.Unlock()
+
+	for _, exporter := range exps {
+		m.adjustExporterQueueDepth(exporter.Name(), 1)
+		exportCtx, exportSpan := tracing.StartSpan(updateCtx, "exporters.Export", map[string]string{"exporter": exporter.Name()})
+		if err := exporter.Export(exportCtx, response); err != nil {
+			m.config.Log.Error().Err(err).Msgf("Exporter %s failed to export an update.", exporter.Name())
+			metrics.DroppedUpdates.Add(1)
+		}
+		exportSpan.End()
+		m.adjustExporterQueueDepth(exporter.Name(), -1)
+	}
+
+	if broadcast != nil {
+		broadcast(response)
+	}
+}
+
+// adjustExporterQueueDepth updates metrics.ExporterQueueDepth with the number of Export calls currently
+// in flight for the named exporter. Every connected target calls the same exporter concurrently from its
+// own goroutine, so this is a real depth: a slow Export call (e.g. a blocked network write) shows up as a
+// growing count for that exporter rather than silently backing up elsewhere.
+func (m *Manager) adjustExporterQueueDepth(name string, delta int64) {
+	v, _ := m.exporterQueueDepth.LoadOrStore(name, new(int64))
+	depth := atomic.AddInt64(v.(*int64), delta)
+	metrics.SetExporterQueueDepth(name, depth)
+}
+
+// HasLoadedTargets returns true once at least one target loader has completed a Load without error.
+func (m *Manager) HasLoadedTargets() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadedOnce
+}
+
+// StopAccepting stops Connect from dialing any new targets. Targets already connected are unaffected until
+// CloseAll is called.
+func (m *Manager) StopAccepting() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.accepting = false
+}
+
+// CloseAll cancels every active target subscription and waits (up to ctx's deadline) for them to finish
+// releasing their cluster locks and exiting.
+func (m *Manager) CloseAll(ctx context.Context) error {
+	m.mu.Lock()
+	cancels := make([]context.CancelFunc, 0, len(m.targets))
+	for _, cancel := range m.targets {
+		cancels = append(cancels, cancel)
+	}
+	m.mu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		m.mu.Lock()
+		remaining := len(m.targets)
+		m.mu.Unlock()
+		if remaining == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetExporters replaces the set of exporters that every future update is fanned out to.
+func (m *Manager) SetExporters(exps []exporters.Exporter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exporters = exps
+}
+
+// Reconfigure swaps in newConfig and connects to any targets newly returned by the (re)enabled target
+// loaders. Targets dropped from the loader output are left connected and keep being reconnected until
+// CloseAll is called.
+func (m *Manager) Reconfigure(newConfig *configuration.GatewayConfig) error {
+	m.mu.Lock()
+	m.config = newConfig
+	m.mu.Unlock()
+	return m.loadTargets(newConfig)
+}
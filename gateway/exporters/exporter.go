@@ -0,0 +1,82 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exporters defines the Exporter interface that every update received from a target is fanned out
+// to, and the registry individual exporter implementations register themselves with.
+package exporters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+// Exporter is implemented by every destination that gateway updates can be sent to (a log, a metrics
+// backend, a message queue, etc).
+type Exporter interface {
+	// Name identifies this exporter in logs and in the -Exporters flag.
+	Name() string
+	// Export is called once for every SubscribeResponse received from any connected target.
+	Export(ctx context.Context, response *gnmipb.SubscribeResponse) error
+	// Flush blocks until every update already accepted by Export has been durably written, or ctx is
+	// cancelled. It is called on every exporter during a graceful Gateway shutdown.
+	Flush(ctx context.Context) error
+}
+
+// Factory builds a new Exporter instance from config. Implementations register a Factory with Register.
+type Factory func(config *configuration.GatewayConfig) Exporter
+
+var (
+	mu        sync.Mutex
+	factories = make(map[string]Factory)
+)
+
+// Register associates name (as used in the -Exporters flag) with factory. Register is expected to be called
+// from an init() function in the exporter's package.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// New builds the exporter registered under name.
+func New(name string, config *configuration.GatewayConfig) (Exporter, error) {
+	mu.Lock()
+	factory, ok := factories[name]
+	mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown exporter %q", name)
+	}
+	return factory(config), nil
+}
+
+// Enabled builds every exporter named in names. An exporter that fails to build (e.g. an unknown name) is
+// logged and skipped rather than failing the whole gateway.
+func Enabled(names []string, config *configuration.GatewayConfig) []Exporter {
+	var out []Exporter
+	for _, name := range names {
+		exporter, err := New(name, config)
+		if err != nil {
+			config.Log.Error().Err(err).Msgf("Unable to start exporter %q.", name)
+			continue
+		}
+		out = append(out, exporter)
+	}
+	return out
+}
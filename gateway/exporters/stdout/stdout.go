@@ -0,0 +1,51 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stdout is the simplest possible Exporter: it logs every update it receives. It is mainly useful
+// for smoke-testing a new target loader or gateway deployment.
+package stdout
+
+import (
+	"context"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/exporters"
+)
+
+func init() {
+	exporters.Register("stdout", func(config *configuration.GatewayConfig) exporters.Exporter {
+		return &stdoutExporter{config: config}
+	})
+}
+
+type stdoutExporter struct {
+	config *configuration.GatewayConfig
+}
+
+func (e *stdoutExporter) Name() string {
+	return "stdout"
+}
+
+func (e *stdoutExporter) Export(_ context.Context, response *gnmipb.SubscribeResponse) error {
+	e.config.Log.Info().Msgf("stdout exporter received update: %s", response.String())
+	return nil
+}
+
+// Flush is a no-op: every update is already written (to the log) synchronously in Export.
+func (e *stdoutExporter) Flush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"sync"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmi-gateway/gateway/cache"
+	"github.com/openconfig/gnmi-gateway/gateway/cluster"
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/connections"
+	"github.com/openconfig/gnmi-gateway/gateway/exporters"
+	"github.com/openconfig/gnmi-gateway/gateway/internal/metrics"
+)
+
+// StartOpts carries the runtime options for StartGateway. It is currently empty; it exists so that options
+// can be added without breaking the StartGateway signature.
+type StartOpts struct{}
+
+// Gateway ties together the cluster.Coordinator, the connections.Manager, the cache, and the configured
+// Exporters into a single running instance. A Gateway is only good for a single call to StartGateway.
+type Gateway struct {
+	config      *configuration.GatewayConfig
+	coordinator cluster.Coordinator
+	connMgr     *connections.Manager
+	cache       *cache.Cache
+
+	mu        sync.Mutex
+	exporters []exporters.Exporter
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	electionCtx    context.Context
+	cancelElection context.CancelFunc
+	electionDone   bool
+	leader         bool
+
+	subMu       sync.Mutex
+	subscribers map[int]chan *gnmipb.SubscribeResponse
+	nextSubID   int
+	subsClosed  bool
+}
+
+// NewGateway builds a Gateway from config. If the configured cluster.Coordinator backend fails to build
+// (e.g. a misconfigured -ClusterBackend), NewGateway falls back to a no-op coordinator and logs the error
+// rather than failing startup, since a standalone instance is still useful.
+func NewGateway(config *configuration.GatewayConfig) *Gateway {
+	coordinator, err := cluster.NewCoordinator(config)
+	if err != nil {
+		config.Log.Error().Err(err).Msg("Unable to build the configured cluster coordinator; falling back to standalone mode.")
+		coordinator = cluster.NewNoopCoordinator()
+	}
+
+	c := cache.NewCache()
+	exps := exporters.Enabled(config.Exporters.Enabled, config)
+	connMgr := connections.NewManager(config, coordinator, c, exps)
+
+	gw := &Gateway{
+		config:      config,
+		coordinator: coordinator,
+		connMgr:     connMgr,
+		cache:       c,
+		exporters:   exps,
+		stopCh:      make(chan struct{}),
+		subscribers: make(map[int]chan *gnmipb.SubscribeResponse),
+	}
+	connMgr.SetBroadcast(gw.broadcast)
+	return gw
+}
+
+// StartGateway opens the connection to the cluster coordinator, registers this instance as a cluster
+// member, runs leader election in the background, and starts connecting to targets. StartGateway blocks
+// until Shutdown is called or the coordinator fails to start.
+func (g *Gateway) StartGateway(opts *StartOpts) error {
+	if err := g.coordinator.Start(); err != nil {
+		return err
+	}
+	if err := g.coordinator.RegisterMember(g.config.ClusterMemberID); err != nil {
+		g.config.Log.Error().Err(err).Msg("Unable to register this instance as a cluster member.")
+	}
+
+	g.electionCtx, g.cancelElection = context.WithCancel(context.Background())
+	go g.runLeaderElection(g.electionCtx)
+
+	if err := g.connMgr.Start(); err != nil {
+		g.config.Log.Error().Err(err).Msg("Unable to load the initial target list.")
+	}
+
+	<-g.stopCh
+	return nil
+}
+
+// runLeaderElection blocks in cluster.Coordinator.LeaderElection until this instance becomes the leader or
+// ctx is cancelled (on Shutdown), recording the outcome in metrics.LeaderStatus.
+func (g *Gateway) runLeaderElection(ctx context.Context) {
+	err := g.coordinator.LeaderElection(ctx)
+	g.mu.Lock()
+	g.electionDone = true
+	g.leader = err == nil
+	g.mu.Unlock()
+	if err != nil {
+		g.config.Log.Warn().Err(err).Msg("Leader election did not complete.")
+		return
+	}
+	metrics.SetLeader(true)
+	g.config.Log.Info().Msgf("This instance (%s) is now the cluster leader.", g.config.ClusterMemberID)
+}
+
+// Ready reports whether this instance has completed its first leader election attempt and loaded at least
+// one target list. It backs the /readyz endpoint on the admin server.
+func (g *Gateway) Ready() bool {
+	g.mu.Lock()
+	electionDone := g.electionDone
+	g.mu.Unlock()
+	return electionDone && g.connMgr.HasLoadedTargets()
+}
+
+// Shutdown drains the Gateway in order: stop accepting new target connections and subscribers (closing the
+// channels of existing ones), flush every exporter, close all target connections (releasing their cluster
+// locks), deregister this instance from the cluster, and close the coordinator (releasing cluster
+// leadership). Shutdown only runs this sequence once; later calls are no-ops that return nil.
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	var shutdownErr error
+	g.stopOnce.Do(func() {
+		g.connMgr.StopAccepting()
+		g.closeSubscribers()
+
+		g.mu.Lock()
+		exps := append([]exporters.Exporter(nil), g.exporters...)
+		g.mu.Unlock()
+		for _, exporter := range exps {
+			if err := exporter.Flush(ctx); err != nil {
+				g.config.Log.Error().Err(err).Msgf("Exporter %s failed to flush during shutdown.", exporter.Name())
+				shutdownErr = err
+			}
+		}
+
+		if err := g.connMgr.CloseAll(ctx); err != nil {
+			g.config.Log.Error().Err(err).Msg("Not every target connection closed before the shutdown deadline.")
+			shutdownErr = err
+		}
+
+		if g.cancelElection != nil {
+			g.cancelElection()
+		}
+		if err := g.coordinator.DeregisterMember(g.config.ClusterMemberID); err != nil {
+			g.config.Log.Error().Err(err).Msg("Unable to deregister this instance from the cluster.")
+		}
+		if err := g.coordinator.Close(); err != nil {
+			g.config.Log.Error().Err(err).Msg("Unable to close the cluster coordinator.")
+		}
+		metrics.SetLeader(false)
+
+		close(g.stopCh)
+	})
+	return shutdownErr
+}
+
+// Reconfigure applies newConfig to the running Gateway: it adds caller information to the logger when
+// LogCaller is newly enabled (disabling it does not remove caller information from the logger), rebuilds
+// every exporter enabled in newConfig (every previous exporter instance, including ones still enabled, is
+// flushed and dropped), and hands the new config to the connections.Manager so that any newly enabled target
+// loaders are used.
+func (g *Gateway) Reconfigure(newConfig *configuration.GatewayConfig) error {
+	g.mu.Lock()
+	oldExporters := g.exporters
+	oldLogCaller := g.config.LogCaller
+	g.mu.Unlock()
+
+	if newConfig.LogCaller != oldLogCaller {
+		if newConfig.LogCaller {
+			newConfig.Log = newConfig.Log.With().Caller().Logger()
+		}
+	}
+
+	newExps := exporters.Enabled(newConfig.Exporters.Enabled, newConfig)
+
+	g.mu.Lock()
+	g.exporters = newExps
+	g.config = newConfig
+	g.mu.Unlock()
+
+	g.connMgr.SetExporters(newExps)
+
+	for _, exporter := range oldExporters {
+		if err := exporter.Flush(context.Background()); err != nil {
+			newConfig.Log.Error().Err(err).Msgf("Exporter %s failed to flush during reconfigure.", exporter.Name())
+		}
+	}
+
+	return g.connMgr.Reconfigure(newConfig)
+}
+
+// RegisterSubscriber returns a channel that receives every SubscribeResponse broadcast from any connected
+// target. The caller must keep draining the channel; it is closed when Shutdown is called, or returned
+// already closed if Shutdown has already run.
+func (g *Gateway) RegisterSubscriber() (int, <-chan *gnmipb.SubscribeResponse) {
+	g.subMu.Lock()
+	defer g.subMu.Unlock()
+	id := g.nextSubID
+	g.nextSubID++
+	ch := make(chan *gnmipb.SubscribeResponse, 1024)
+	if g.subsClosed {
+		close(ch)
+		return id, ch
+	}
+	g.subscribers[id] = ch
+	return id, ch
+}
+
+// UnregisterSubscriber removes and closes the channel previously returned by RegisterSubscriber.
+func (g *Gateway) UnregisterSubscriber(id int) {
+	g.subMu.Lock()
+	defer g.subMu.Unlock()
+	if ch, ok := g.subscribers[id]; ok {
+		delete(g.subscribers, id)
+		close(ch)
+	}
+}
+
+func (g *Gateway) broadcast(response *gnmipb.SubscribeResponse) {
+	g.subMu.Lock()
+	defer g.subMu.Unlock()
+	for _, ch := range g.subscribers {
+		select {
+		case ch <- response:
+		default:
+			metrics.DroppedUpdates.Add(1)
+		}
+	}
+}
+
+func (g *Gateway) closeSubscribers() {
+	g.subMu.Lock()
+	defer g.subMu.Unlock()
+	g.subsClosed = true
+	for id, ch := range g.subscribers {
+		delete(g.subscribers, id)
+		close(ch)
+	}
+}
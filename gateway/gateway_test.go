@@ -0,0 +1,126 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	_ "github.com/openconfig/gnmi-gateway/gateway/exporters/all"
+	_ "github.com/openconfig/gnmi-gateway/gateway/loaders/all"
+)
+
+func newTestConfig(t *testing.T) *configuration.GatewayConfig {
+	t.Helper()
+	config := configuration.NewDefaultGatewayConfig()
+	config.ClusterBackend = "none"
+	config.ShutdownTimeout = 5 * time.Second
+
+	targetFile := filepath.Join(t.TempDir(), "targets.yaml")
+	if err := os.WriteFile(targetFile, []byte("[]\n"), 0644); err != nil {
+		t.Fatalf("unable to write temp target file: %v", err)
+	}
+	config.TargetLoaders.Enabled = []string{"simple"}
+	config.TargetLoaders.SimpleFile = targetFile
+	config.Exporters.Enabled = []string{"stdout"}
+	return config
+}
+
+func TestGatewayBecomesReadyAfterStart(t *testing.T) {
+	config := newTestConfig(t)
+	gw := NewGateway(config)
+
+	done := make(chan error, 1)
+	go func() { done <- gw.StartGateway(new(StartOpts)) }()
+
+	deadline := time.After(2 * time.Second)
+	for !gw.Ready() {
+		select {
+		case <-deadline:
+			t.Fatal("gateway never became ready")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned an error: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("StartGateway returned an error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("StartGateway did not return after Shutdown")
+	}
+}
+
+func TestGatewayShutdownIsIdempotent(t *testing.T) {
+	config := newTestConfig(t)
+	gw := NewGateway(config)
+	go gw.StartGateway(new(StartOpts))
+
+	deadline := time.After(2 * time.Second)
+	for !gw.Ready() {
+		select {
+		case <-deadline:
+			t.Fatal("gateway never became ready")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatalf("first Shutdown returned an error: %v", err)
+	}
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown returned an error: %v", err)
+	}
+}
+
+func TestGatewayShutdownClosesSubscribers(t *testing.T) {
+	config := newTestConfig(t)
+	gw := NewGateway(config)
+	go gw.StartGateway(new(StartOpts))
+
+	deadline := time.After(2 * time.Second)
+	for !gw.Ready() {
+		select {
+		case <-deadline:
+			t.Fatal("gateway never became ready")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	_, ch := gw.RegisterSubscriber()
+
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned an error: %v", err)
+	}
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("subscriber channel delivered a value instead of closing")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscriber channel was never closed")
+	}
+}
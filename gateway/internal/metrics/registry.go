@@ -0,0 +1,51 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics is the central registry of expvar counters published by the gateway under /debug/vars on
+// the admin server. The connections, cache, and exporters packages update these variables directly so that
+// operators can scrape gateway internals without enabling the Prometheus exporter.
+package metrics
+
+import "expvar"
+
+var (
+	// ConnectedTargets is the current number of targets this instance holds a live gNMI subscription to.
+	ConnectedTargets = expvar.NewInt("gnmi_gateway_connected_targets")
+	// CacheSize is the current number of (target, prefix) Notification entries held in the in-memory gNMI cache.
+	CacheSize = expvar.NewInt("gnmi_gateway_cache_size")
+	// DroppedUpdates counts SubscribeResponse updates dropped because a subscriber buffer was full or an
+	// exporter's Export call returned an error.
+	DroppedUpdates = expvar.NewInt("gnmi_gateway_dropped_updates")
+	// LeaderStatus is 1 if this instance currently holds cluster leadership, 0 otherwise.
+	LeaderStatus = expvar.NewInt("gnmi_gateway_leader_status")
+	// ExporterQueueDepth is the current queue depth of each running exporter, keyed by exporter name.
+	ExporterQueueDepth = expvar.NewMap("gnmi_gateway_exporter_queue_depth")
+)
+
+// SetExporterQueueDepth records the current queue depth for the named exporter.
+func SetExporterQueueDepth(name string, depth int64) {
+	var v expvar.Int
+	v.Set(depth)
+	ExporterQueueDepth.Set(name, &v)
+}
+
+// SetLeader records whether this instance currently holds cluster leadership.
+func SetLeader(isLeader bool) {
+	if isLeader {
+		LeaderStatus.Set(1)
+	} else {
+		LeaderStatus.Set(0)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonfile loads targets from the JSON array of target addresses at -TargetJSONFile.
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/loaders"
+)
+
+func init() {
+	loaders.Register("json", func(config *configuration.GatewayConfig) loaders.Loader {
+		return &jsonFileLoader{config: config}
+	})
+}
+
+type jsonFileLoader struct {
+	config *configuration.GatewayConfig
+}
+
+func (l *jsonFileLoader) Name() string {
+	return "json"
+}
+
+func (l *jsonFileLoader) Load() ([]string, error) {
+	if l.config.TargetLoaders.JSONFile == "" {
+		return nil, fmt.Errorf("-TargetJSONFile must be set to use the json target loader")
+	}
+	data, err := os.ReadFile(l.config.TargetLoaders.JSONFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", l.config.TargetLoaders.JSONFile, err)
+	}
+	var targets []string
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", l.config.TargetLoaders.JSONFile, err)
+	}
+	return targets, nil
+}
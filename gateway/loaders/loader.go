@@ -0,0 +1,61 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loaders defines the Loader interface used to discover the targets a gateway instance should
+// connect to, and the registry individual loader implementations register themselves with.
+package loaders
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+// Loader returns the set of target addresses the gateway should connect to. Load may be called more than
+// once over the life of a Loader (e.g. on a reload interval or a SIGHUP config reload).
+type Loader interface {
+	// Name identifies this loader in logs and in the -TargetLoaders flag.
+	Name() string
+	// Load returns the current set of target addresses.
+	Load() ([]string, error)
+}
+
+// Factory builds a new Loader instance from config. Implementations register a Factory with Register.
+type Factory func(config *configuration.GatewayConfig) Loader
+
+var (
+	mu        sync.Mutex
+	factories = make(map[string]Factory)
+)
+
+// Register associates name (as used in the -TargetLoaders flag) with factory. Register is expected to be
+// called from an init() function in the loader's package.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// New builds the loader registered under name.
+func New(name string, config *configuration.GatewayConfig) (Loader, error) {
+	mu.Lock()
+	factory, ok := factories[name]
+	mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown target loader %q", name)
+	}
+	return factory(config), nil
+}
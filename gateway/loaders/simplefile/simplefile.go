@@ -0,0 +1,56 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simplefile loads targets from the plain YAML list of target addresses at -SimpleFile.
+package simplefile
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+	"github.com/openconfig/gnmi-gateway/gateway/loaders"
+)
+
+func init() {
+	loaders.Register("simple", func(config *configuration.GatewayConfig) loaders.Loader {
+		return &simpleFileLoader{config: config}
+	})
+}
+
+type simpleFileLoader struct {
+	config *configuration.GatewayConfig
+}
+
+func (l *simpleFileLoader) Name() string {
+	return "simple"
+}
+
+func (l *simpleFileLoader) Load() ([]string, error) {
+	if l.config.TargetLoaders.SimpleFile == "" {
+		return nil, fmt.Errorf("-SimpleFile must be set to use the simple target loader")
+	}
+	data, err := os.ReadFile(l.config.TargetLoaders.SimpleFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", l.config.TargetLoaders.SimpleFile, err)
+	}
+	var targets []string
+	if err := yaml.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", l.config.TargetLoaders.SimpleFile, err)
+	}
+	return targets, nil
+}
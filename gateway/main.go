@@ -16,21 +16,27 @@
 package gateway
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/openconfig/gnmi-gateway/gateway/configuration"
 	_ "github.com/openconfig/gnmi-gateway/gateway/exporters/all"
 	_ "github.com/openconfig/gnmi-gateway/gateway/loaders/all"
-	"net/http"
+	"github.com/openconfig/gnmi-gateway/gateway/tracing"
 	"os"
 	"os/signal"
 	"runtime/pprof"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
+// ConfigFile is the path passed via -ConfigFile. It is kept around (rather than a local in ParseArgs) so
+// that a SIGHUP can re-read the same file for a config reload.
+var ConfigFile string
+
 // Main is the entry point for the command-line and it's a good example of how to call StartGateway but
 // other than that you probably don't need Main for anything.
 func Main() {
@@ -47,17 +53,17 @@ func Main() {
 	}
 
 	var deferred []func()
-	c := make(chan os.Signal, 2)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		config.Log.Info().Msg("Ctrl^C pressed.")
-		for _, deferredFunc := range deferred {
-			deferredFunc()
+
+	tracingShutdown, err := tracing.Init(context.Background(), config)
+	if err != nil {
+		config.Log.Error().Err(err).Msgf("Unable to setup tracing: %v", err)
+		os.Exit(1)
+	}
+	deferred = append(deferred, func() {
+		if err := tracingShutdown(context.Background()); err != nil {
+			config.Log.Error().Err(err).Msg("Error shutting down tracing.")
 		}
-		config.Log.Info().Msg("Exit.")
-		os.Exit(0)
-	}()
+	})
 
 	debugCleanup, err := SetupDebugging(config)
 	if err != nil {
@@ -69,10 +75,77 @@ func Main() {
 		}
 	}
 
+	gateway := NewGateway(config) // tracing must be initialized before NewGateway so that startup spans are captured
+
+	adminCleanup, err := StartAdminServer(config, gateway)
+	if err != nil {
+		config.Log.Error().Err(err).Msgf("Unable to start admin server: %v", err)
+		os.Exit(1)
+	}
+	deferred = append(deferred, adminCleanup)
+
+	// configRef holds the config currently in effect. It's read by the SIGTERM/SIGINT handler below and
+	// swapped by the SIGHUP handler on a successful reload, and the two can fire concurrently (e.g. a config
+	// push landing during a rolling restart), so plain reads/writes of a shared *GatewayConfig would race.
+	var configRef atomic.Pointer[configuration.GatewayConfig]
+	configRef.Store(config)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			current := configRef.Load()
+			current.Log.Info().Msg("Received SIGHUP, reloading configuration.")
+			staged, err := reloadConfig(current)
+			if err != nil {
+				current.Log.Error().Err(err).Msgf("Unable to reload configuration, keeping existing config: %v", err)
+				continue
+			}
+			rejectImmutableChanges(current, staged)
+			if err := gateway.Reconfigure(staged); err != nil {
+				current.Log.Error().Err(err).Msgf("Unable to apply reloaded configuration: %v", err)
+			} else {
+				configRef.Store(staged)
+			}
+		}
+	}()
+
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-c
+		current := configRef.Load()
+		current.Log.Info().Msgf("Received %v, starting graceful shutdown (up to %v).", sig, current.ShutdownTimeout)
+
+		done := make(chan struct{})
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), current.ShutdownTimeout)
+			defer cancel()
+			if err := gateway.Shutdown(ctx); err != nil {
+				current.Log.Error().Err(err).Msg("Error during graceful shutdown.")
+			}
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			current.Log.Info().Msg("Graceful shutdown complete.")
+		case <-c:
+			current.Log.Warn().Msg("Second signal received, forcing immediate exit.")
+		case <-time.After(current.ShutdownTimeout):
+			current.Log.Warn().Msg("Graceful shutdown timed out, forcing exit.")
+		}
+
+		for _, deferredFunc := range deferred {
+			deferredFunc()
+		}
+		current.Log.Info().Msg("Exit.")
+		os.Exit(0)
+	}()
+
 	opts := new(StartOpts)
 
-	gateway := NewGateway(config)
-	err = gateway.StartGateway(opts) // run forever (or until an error happens)
+	err = gateway.StartGateway(opts) // run forever (or until an error happens, or Shutdown is called)
 	if err != nil {
 		config.Log.Error().Msgf("Gateway exited with an error: %v", err)
 		os.Exit(1)
@@ -89,7 +162,8 @@ func ParseArgs(config *configuration.GatewayConfig) error {
 	flag.BoolVar(&PrintVersion, "version", false, "Print version and exit")
 
 	// Configuration Parameters
-	configFile := flag.String("ConfigFile", "", "Path of the gateway configuration JSON file.")
+	flag.StringVar(&config.AdminListenAddress, "AdminListenAddress", "127.0.0.1:6161", "Address for the admin HTTP server (pprof, expvar metrics, health/readiness checks) to listen on")
+	flag.StringVar(&ConfigFile, "ConfigFile", "", "Path of the gateway configuration JSON file.")
 	flag.BoolVar(&config.EnableGNMIServer, "EnableGNMIServer", false, "Enable the gNMI server")
 	exporters := flag.String("Exporters", "", "Comma-separated list of Exporters to enable.")
 	flag.Uint64Var(&config.GatewayTransitionBufferSize, "GatewayTransitionBufferSize", 10000, "Tunes the size of the buffer between targets and exporters/clients")
@@ -101,6 +175,7 @@ func ParseArgs(config *configuration.GatewayConfig) error {
 	flag.IntVar(&config.ServerListenPort, "ServerListenPort", 9339, "TCP port to run the gNMI server on")
 	flag.StringVar(&config.ServerTLSCert, "ServerTLSCert", "", "File containing the gNMI server TLS certificate (required to enable the gNMI server)")
 	flag.StringVar(&config.ServerTLSKey, "ServerTLSKey", "", "File containing the gNMI server TLS key (required to enable the gNMI server)")
+	flag.DurationVar(&config.ShutdownTimeout, "ShutdownTimeout", 30*time.Second, "Maximum time to wait for in-flight subscriptions and exporters to drain during a graceful shutdown before forcing exit")
 	flag.StringVar(&config.TargetLoaders.SimpleFile, "SimpleFile", "", "Simple YAML file containing the target configurations")
 	flag.DurationVar(&config.TargetLoaders.SimpleFileReloadInterval, "SimpleFileReloadInterval", 30*time.Second, "Interval to reload the simple YAML file containing the target configurations")
 	flag.StringVar(&config.StatsSpectatorURI, "StatsSpectatorURI", "", "URI for Atlas server to send Spectator metrics to (required to enable sending internal gateway stats to Atlas)")
@@ -109,17 +184,25 @@ func ParseArgs(config *configuration.GatewayConfig) error {
 	flag.DurationVar(&config.TargetLoaders.JSONFileReloadInterval, "TargetJSONFileReloadInterval", 30*time.Second, "Interval to reload the JSON file containing the target configurations")
 	flag.DurationVar(&config.TargetDialTimeout, "TargetDialTimeout", 10*time.Second, "Dial timeout time")
 	flag.IntVar(&config.TargetLimit, "TargetLimit", 100, "Maximum number of targets that this instance will connect to at once")
+	flag.StringVar(&config.ClusterBackend, "ClusterBackend", "zookeeper", "Cluster coordination backend to use: zookeeper, etcd, or none")
 	zkHosts := flag.String("ZookeeperHosts", "", "Comma separated (no spaces) list of zookeeper hosts including port")
 	flag.StringVar(&config.ZookeeperPrefix, "ZookeeperPrefix", "/gnmi/gateway/", "Prefix for the lock path in Zookeeper")
 	flag.DurationVar(&config.ZookeeperTimeout, "ZookeeperTimeout", 1*time.Second, "Zookeeper timeout time. Minimum is 1 second. Failover time is (ZookeeperTimeout * 2)")
+	etcdEndpoints := flag.String("EtcdEndpoints", "", "Comma separated (no spaces) list of etcd endpoints including port")
+	flag.StringVar(&config.EtcdPrefix, "EtcdPrefix", "/gnmi/gateway/", "Prefix for the lock and membership keys in etcd")
+	flag.DurationVar(&config.EtcdDialTimeout, "EtcdDialTimeout", 5*time.Second, "etcd client dial timeout")
+	flag.StringVar(&config.TracingExporter, "TracingExporter", "none", "OpenTelemetry trace exporter to use: otlp, jaeger, or none")
+	flag.StringVar(&config.TracingEndpoint, "TracingEndpoint", "", "Collector endpoint for the configured -TracingExporter")
+	flag.Float64Var(&config.TracingSampleRatio, "TracingSampleRatio", 1.0, "Fraction of traces to sample, between 0 and 1")
 
 	flag.Parse()
 	config.Exporters.Enabled = cleanSplit(*exporters)
 	config.TargetLoaders.Enabled = cleanSplit(*targetLoaders)
 	config.ZookeeperHosts = cleanSplit(*zkHosts)
+	config.EtcdEndpoints = cleanSplit(*etcdEndpoints)
 
-	if *configFile != "" {
-		err := configuration.PopulateGatewayConfigFromFile(config, *configFile)
+	if ConfigFile != "" {
+		err := configuration.PopulateGatewayConfigFromFile(config, ConfigFile)
 		if err != nil {
 			return fmt.Errorf("failed to populate config from file: %v", err)
 		}
@@ -132,6 +215,48 @@ func ParseArgs(config *configuration.GatewayConfig) error {
 	return nil
 }
 
+// reloadConfig builds a new GatewayConfig for use as the staged config in a SIGHUP reload. It starts from a
+// copy of current (so values set by command-line flags, which cannot be re-parsed after startup, are kept)
+// and overlays -ConfigFile and the environment on top.
+func reloadConfig(current *configuration.GatewayConfig) (*configuration.GatewayConfig, error) {
+	staged := configuration.NewDefaultGatewayConfig()
+	*staged = *current
+	if ConfigFile != "" {
+		if err := configuration.PopulateGatewayConfigFromFile(staged, ConfigFile); err != nil {
+			return nil, fmt.Errorf("failed to populate config from file: %v", err)
+		}
+	}
+	if err := envconfig.Process("gateway", staged); err != nil {
+		return nil, fmt.Errorf("failed to read environment variable configuration: %v", err)
+	}
+	return staged, nil
+}
+
+// rejectImmutableChanges reverts any field on staged that cannot be safely changed without restarting the
+// process (e.g. the listen port or the Zookeeper ensemble), logging a warning for each one reverted.
+func rejectImmutableChanges(current, staged *configuration.GatewayConfig) {
+	if staged.ServerListenPort != current.ServerListenPort {
+		current.Log.Warn().Msgf("ServerListenPort cannot be changed with a SIGHUP reload; keeping %d.", current.ServerListenPort)
+		staged.ServerListenPort = current.ServerListenPort
+	}
+	if !stringSlicesEqual(staged.ZookeeperHosts, current.ZookeeperHosts) {
+		current.Log.Warn().Msg("ZookeeperHosts cannot be changed with a SIGHUP reload; keeping the existing ensemble.")
+		staged.ZookeeperHosts = current.ZookeeperHosts
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func cleanSplit(in string) []string {
 	var out []string
 	for _, s := range strings.Split(in, ",") {
@@ -143,7 +268,8 @@ func cleanSplit(in string) []string {
 	return out
 }
 
-// SetupDebugging optionally sets up debugging features including -LogCaller and -PProf.
+// SetupDebugging optionally sets up debugging features including -LogCaller and -CPUProfile. The pprof web
+// server itself is mounted on the admin server started by StartAdminServer, not here.
 func SetupDebugging(config *configuration.GatewayConfig) (func(), error) {
 	var deferFuncs []func()
 
@@ -151,16 +277,6 @@ func SetupDebugging(config *configuration.GatewayConfig) (func(), error) {
 		config.Log = config.Log.With().Caller().Logger()
 	}
 
-	if PProf {
-		port := ":6161"
-		go func() {
-			if err := http.ListenAndServe(port, nil); err != nil {
-				config.Log.Error().Err(err).Msgf("error starting pprof web server: %v", err)
-			}
-			config.Log.Info().Msgf("Launched pprof web server on %v", port)
-		}()
-	}
-
 	if CPUProfile != "" {
 		f, err := os.Create(CPUProfile)
 		if err != nil {
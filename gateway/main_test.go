@@ -0,0 +1,93 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+func TestReloadConfigPreservesExistingValues(t *testing.T) {
+	ConfigFile = ""
+	current := configuration.NewDefaultGatewayConfig()
+	current.TargetLimit = 42
+
+	staged, err := reloadConfig(current)
+	if err != nil {
+		t.Fatalf("reloadConfig returned an error: %v", err)
+	}
+	if staged.TargetLimit != current.TargetLimit {
+		t.Errorf("TargetLimit = %d, want %d", staged.TargetLimit, current.TargetLimit)
+	}
+}
+
+func TestRejectImmutableChangesRevertsListenPort(t *testing.T) {
+	current := configuration.NewDefaultGatewayConfig()
+	current.ServerListenPort = 9339
+	staged := configuration.NewDefaultGatewayConfig()
+	staged.ServerListenPort = 1234
+
+	rejectImmutableChanges(current, staged)
+
+	if staged.ServerListenPort != current.ServerListenPort {
+		t.Errorf("ServerListenPort = %d, want the immutable current value %d", staged.ServerListenPort, current.ServerListenPort)
+	}
+}
+
+func TestRejectImmutableChangesRevertsZookeeperHosts(t *testing.T) {
+	current := configuration.NewDefaultGatewayConfig()
+	current.ZookeeperHosts = []string{"zk1:2181", "zk2:2181"}
+	staged := configuration.NewDefaultGatewayConfig()
+	staged.ZookeeperHosts = []string{"zk3:2181"}
+
+	rejectImmutableChanges(current, staged)
+
+	if !stringSlicesEqual(staged.ZookeeperHosts, current.ZookeeperHosts) {
+		t.Errorf("ZookeeperHosts = %v, want the immutable current value %v", staged.ZookeeperHosts, current.ZookeeperHosts)
+	}
+}
+
+func TestRejectImmutableChangesAllowsMutableFields(t *testing.T) {
+	current := configuration.NewDefaultGatewayConfig()
+	current.ServerListenPort = 9339
+	staged := configuration.NewDefaultGatewayConfig()
+	staged.ServerListenPort = 9339
+	staged.TargetLimit = 500
+
+	rejectImmutableChanges(current, staged)
+
+	if staged.TargetLimit != 500 {
+		t.Errorf("TargetLimit = %d, want it untouched at 500", staged.TargetLimit)
+	}
+}
+
+func TestStringSlicesEqual(t *testing.T) {
+	cases := []struct {
+		a, b []string
+		want bool
+	}{
+		{nil, nil, true},
+		{[]string{"a"}, []string{"a"}, true},
+		{[]string{"a"}, []string{"b"}, false},
+		{[]string{"a"}, []string{"a", "b"}, false},
+	}
+	for _, c := range cases {
+		if got := stringSlicesEqual(c.a, c.b); got != c.want {
+			t.Errorf("stringSlicesEqual(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
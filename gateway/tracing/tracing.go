@@ -0,0 +1,108 @@
+// Copyright 2020 Netflix Inc
+// Author: Colin McIntosh (colin@netflix.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing wires up the OpenTelemetry tracer provider used across the gateway (target dial/reconnect,
+// cache writes, cluster lock acquisition, and exporter pipelines) so operators can see which subsystem is
+// stalling under load.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openconfig/gnmi-gateway/gateway/configuration"
+)
+
+// tracerName identifies the gateway's own instrumentation to whatever tracer provider is installed.
+const tracerName = "github.com/openconfig/gnmi-gateway/gateway"
+
+// StartSpan starts a span named name as a child of ctx, with attrs attached as string attributes. Every
+// instrumented call site (target dial/reconnect, cache writes, cluster lock acquisition, exporter Export
+// calls) uses this instead of calling otel.Tracer directly so they all report under the same tracer name.
+// Before Init runs (or when tracing is disabled), the global tracer provider is a no-op and the returned
+// span does nothing.
+func StartSpan(ctx context.Context, name string, attrs map[string]string) (context.Context, trace.Span) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(kvs...))
+}
+
+// Init configures the global OpenTelemetry tracer provider according to config.TracingExporter,
+// config.TracingEndpoint, and config.TracingSampleRatio. The returned shutdown func flushes and closes the
+// exporter and must be called (e.g. appended to Main's deferred cleanups) before the process exits. If
+// config.TracingExporter is "none" (the default), Init leaves the global no-op tracer provider in place and
+// the shutdown func is a no-op.
+func Init(ctx context.Context, config *configuration.GatewayConfig) (func(context.Context) error, error) {
+	switch config.TracingExporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		return initOTLP(ctx, config)
+	case "jaeger":
+		return initJaeger(config)
+	default:
+		return nil, fmt.Errorf("unknown -TracingExporter %q: must be one of otlp, jaeger, none", config.TracingExporter)
+	}
+}
+
+func initOTLP(ctx context.Context, config *configuration.GatewayConfig) (func(context.Context) error, error) {
+	client := otlptracegrpc.NewClient(otlptracegrpc.WithEndpoint(config.TracingEndpoint), otlptracegrpc.WithInsecure())
+	exporter, err := otlptrace.New(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create OTLP trace exporter: %v", err)
+	}
+	return installProvider(config, exporter)
+}
+
+func initJaeger(config *configuration.GatewayConfig) (func(context.Context) error, error) {
+	exporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(config.TracingEndpoint)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Jaeger trace exporter: %v", err)
+	}
+	return installProvider(config, exporter)
+}
+
+func installProvider(config *configuration.GatewayConfig, exporter sdktrace.SpanExporter) (func(context.Context) error, error) {
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(
+			semconv.ServiceNameKey.String("gnmi-gateway"),
+			semconv.ServiceInstanceIDKey.String(config.ClusterMemberID),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build trace resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(config.TracingSampleRatio)),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}